@@ -0,0 +1,231 @@
+package main
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "math"
+    "sort"
+)
+
+// decodeWeightVector parses a LocalModel.Weights (or GlobalModel.Weights) string into a
+// flat float64 vector. Clients may encode the vector as a plain JSON array or as
+// base64-encoded JSON, so both forms are accepted.
+func decodeWeightVector(weights string) ([]float64, error) {
+    var vector []float64
+    if err := json.Unmarshal([]byte(weights), &vector); err == nil {
+        return vector, nil
+    }
+
+    decoded, err := base64.StdEncoding.DecodeString(weights)
+    if err != nil {
+        return nil, fmt.Errorf("weights is neither a JSON float array nor base64-encoded JSON: %v", err)
+    }
+    if err := json.Unmarshal(decoded, &vector); err != nil {
+        return nil, fmt.Errorf("failed to decode base64 weight vector: %v", err)
+    }
+    return vector, nil
+}
+
+func encodeWeightVector(vector []float64) (string, error) {
+    encoded, err := json.Marshal(vector)
+    if err != nil {
+        return "", err
+    }
+    return string(encoded), nil
+}
+
+func squaredL2Distance(a, b []float64) (float64, error) {
+    if len(a) != len(b) {
+        return 0, fmt.Errorf("vector length mismatch: %d vs %d", len(a), len(b))
+    }
+    var sum float64
+    for i := range a {
+        diff := a[i] - b[i]
+        sum += diff * diff
+    }
+    return sum, nil
+}
+
+// fedAvg computes the dataSize-weighted average of the submitted weight vectors.
+func fedAvg(vectors [][]float64, dataSizes []int) ([]float64, error) {
+    if len(vectors) == 0 {
+        return nil, fmt.Errorf("no weight vectors to aggregate")
+    }
+
+    dim := len(vectors[0])
+    totalDataSize := 0
+    for _, size := range dataSizes {
+        totalDataSize += size
+    }
+    if totalDataSize == 0 {
+        return nil, fmt.Errorf("total data size across clients is zero")
+    }
+
+    result := make([]float64, dim)
+    for i, vector := range vectors {
+        if len(vector) != dim {
+            return nil, fmt.Errorf("weight vector %d has dimension %d, expected %d", i, len(vector), dim)
+        }
+        weight := float64(dataSizes[i]) / float64(totalDataSize)
+        for d := 0; d < dim; d++ {
+            result[d] += vector[d] * weight
+        }
+    }
+    return result, nil
+}
+
+// krumScores computes, for each client i, the sum of the n-f-2 smallest squared L2
+// distances to the other submitted vectors (the Krum score).
+func krumScores(vectors [][]float64, f int) ([]float64, error) {
+    n := len(vectors)
+    if n == 0 {
+        return nil, fmt.Errorf("no weight vectors to score")
+    }
+
+    keep := n - f - 2
+    if keep < 1 {
+        return nil, fmt.Errorf("byzantine tolerance %d too large for %d clients", f, n)
+    }
+
+    distances := make([][]float64, n)
+    for i := 0; i < n; i++ {
+        distances[i] = make([]float64, 0, n-1)
+        for j := 0; j < n; j++ {
+            if i == j {
+                continue
+            }
+            d, err := squaredL2Distance(vectors[i], vectors[j])
+            if err != nil {
+                return nil, err
+            }
+            distances[i] = append(distances[i], d)
+        }
+        sort.Float64s(distances[i])
+    }
+
+    scores := make([]float64, n)
+    for i := 0; i < n; i++ {
+        var sum float64
+        for _, d := range distances[i][:keep] {
+            sum += d
+        }
+        scores[i] = sum
+    }
+    return scores, nil
+}
+
+// krum returns the index of the client whose Krum score is lowest, along with every
+// client's score.
+func krum(vectors [][]float64, f int) (int, []float64, error) {
+    scores, err := krumScores(vectors, f)
+    if err != nil {
+        return -1, nil, err
+    }
+
+    best := 0
+    for i := 1; i < len(scores); i++ {
+        if scores[i] < scores[best] {
+            best = i
+        }
+    }
+    return best, scores, nil
+}
+
+// multiKrum returns the indices of the m clients with the lowest Krum scores, along
+// with every client's score.
+func multiKrum(vectors [][]float64, f int, m int) ([]int, []float64, error) {
+    scores, err := krumScores(vectors, f)
+    if err != nil {
+        return nil, nil, err
+    }
+    if m < 1 {
+        m = 1
+    }
+    if m > len(scores) {
+        m = len(scores)
+    }
+
+    indices := make([]int, len(scores))
+    for i := range indices {
+        indices[i] = i
+    }
+    sort.Slice(indices, func(a, b int) bool { return scores[indices[a]] < scores[indices[b]] })
+
+    return indices[:m], scores, nil
+}
+
+// trimmedMean sorts each coordinate across clients, drops the f largest and f
+// smallest values, and averages what remains.
+func trimmedMean(vectors [][]float64, f int) ([]float64, error) {
+    n := len(vectors)
+    if n == 0 {
+        return nil, fmt.Errorf("no weight vectors to aggregate")
+    }
+    if 2*f >= n {
+        return nil, fmt.Errorf("byzantine tolerance %d too large for %d clients", f, n)
+    }
+
+    dim := len(vectors[0])
+    result := make([]float64, dim)
+    column := make([]float64, n)
+
+    for d := 0; d < dim; d++ {
+        for i, vector := range vectors {
+            if len(vector) != dim {
+                return nil, fmt.Errorf("weight vector %d has dimension %d, expected %d", i, len(vector), dim)
+            }
+            column[i] = vector[d]
+        }
+        sorted := append([]float64(nil), column...)
+        sort.Float64s(sorted)
+        trimmed := sorted[f : n-f]
+
+        var sum float64
+        for _, v := range trimmed {
+            sum += v
+        }
+        result[d] = sum / float64(len(trimmed))
+    }
+
+    return result, nil
+}
+
+// coordinateMedian computes the coordinate-wise median of the submitted weight vectors.
+func coordinateMedian(vectors [][]float64) ([]float64, error) {
+    n := len(vectors)
+    if n == 0 {
+        return nil, fmt.Errorf("no weight vectors to aggregate")
+    }
+
+    dim := len(vectors[0])
+    result := make([]float64, dim)
+    column := make([]float64, n)
+
+    for d := 0; d < dim; d++ {
+        for i, vector := range vectors {
+            if len(vector) != dim {
+                return nil, fmt.Errorf("weight vector %d has dimension %d, expected %d", i, len(vector), dim)
+            }
+            column[i] = vector[d]
+        }
+        sorted := append([]float64(nil), column...)
+        sort.Float64s(sorted)
+
+        mid := n / 2
+        if n%2 == 0 {
+            result[d] = (sorted[mid-1] + sorted[mid]) / 2
+        } else {
+            result[d] = sorted[mid]
+        }
+    }
+
+    return result, nil
+}
+
+// roundFloat rounds to a fixed precision so aggregation scores are stable once
+// serialized to JSON and re-read across peers.
+func roundFloat(v float64, places int) float64 {
+    shift := math.Pow(10, float64(places))
+    return math.Round(v*shift) / shift
+}
@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+func TestFedAvg(t *testing.T) {
+    vectors := [][]float64{{1, 2}, {3, 4}}
+    dataSizes := []int{1, 3}
+
+    result, err := fedAvg(vectors, dataSizes)
+    if err != nil {
+        t.Fatalf("fedAvg returned error: %v", err)
+    }
+
+    want := []float64{2.5, 3.5} // (1*1 + 3*3)/4, (2*1 + 4*3)/4
+    for i := range want {
+        if roundFloat(result[i], 6) != want[i] {
+            t.Errorf("dim %d: got %v, want %v", i, result[i], want[i])
+        }
+    }
+}
+
+func TestKrumScoresSelectsInlier(t *testing.T) {
+    vectors := [][]float64{
+        {0, 0},
+        {0.1, 0.1},
+        {0.2, -0.1},
+        {100, 100}, // outlier
+    }
+
+    index, scores, err := krum(vectors, 1)
+    if err != nil {
+        t.Fatalf("krum returned error: %v", err)
+    }
+    if index == 3 {
+        t.Errorf("krum selected the outlier at index 3, scores: %v", scores)
+    }
+}
+
+func TestKrumScoresRejectsOversizedTolerance(t *testing.T) {
+    vectors := [][]float64{{0, 0}, {1, 1}, {2, 2}}
+
+    if _, err := krumScores(vectors, 2); err == nil {
+        t.Fatal("expected an error when byzantine tolerance leaves fewer than one neighbor to keep, got nil")
+    }
+}
+
+func TestTrimmedMean(t *testing.T) {
+    vectors := [][]float64{{1}, {2}, {3}, {100}}
+
+    result, err := trimmedMean(vectors, 1)
+    if err != nil {
+        t.Fatalf("trimmedMean returned error: %v", err)
+    }
+
+    want := 2.5 // drops 1 and 100, averages 2 and 3
+    if roundFloat(result[0], 6) != want {
+        t.Errorf("got %v, want %v", result[0], want)
+    }
+}
+
+func TestTrimmedMeanRejectsOversizedTolerance(t *testing.T) {
+    vectors := [][]float64{{1}, {2}, {3}}
+
+    if _, err := trimmedMean(vectors, 2); err == nil {
+        t.Fatal("expected an error when 2*f >= n, got nil")
+    }
+}
+
+func TestCoordinateMedian(t *testing.T) {
+    vectors := [][]float64{{1, 10}, {2, 20}, {3, 30}, {4, 40}}
+
+    result, err := coordinateMedian(vectors)
+    if err != nil {
+        t.Fatalf("coordinateMedian returned error: %v", err)
+    }
+
+    want := []float64{2.5, 25}
+    for i := range want {
+        if roundFloat(result[i], 6) != want[i] {
+            t.Errorf("dim %d: got %v, want %v", i, result[i], want[i])
+        }
+    }
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    "github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// richQueryUnsupported reports whether err is the backing state database (LevelDB)
+// rejecting a rich query outright, as opposed to a malformed selector or a genuine
+// CouchDB failure that should be surfaced to the caller instead of masked as a
+// fallback.
+func richQueryUnsupported(err error) bool {
+    if err == nil {
+        return false
+    }
+    msg := strings.ToLower(err.Error())
+    return strings.Contains(msg, "not supported") || strings.Contains(msg, "not implemented")
+}
+
+// ClientsPage is a page of QueryClients results.
+type ClientsPage struct {
+    Results        []*Client `json:"results"`
+    NextBookmark   string    `json:"nextBookmark"`
+    FetchedRecords int32     `json:"fetchedRecords"`
+}
+
+// LocalModelsPage is a page of QueryLocalModels results.
+type LocalModelsPage struct {
+    Results        []*LocalModel `json:"results"`
+    NextBookmark   string        `json:"nextBookmark"`
+    FetchedRecords int32         `json:"fetchedRecords"`
+}
+
+// MetricsPage is a page of QueryTrainingMetrics results.
+type MetricsPage struct {
+    Results        []*TrainingMetrics `json:"results"`
+    NextBookmark   string             `json:"nextBookmark"`
+    FetchedRecords int32              `json:"fetchedRecords"`
+}
+
+// QueryClients runs a paginated Mango rich query over clients, optionally filtered by
+// domain. On a LevelDB-backed channel, where GetQueryResultWithPagination is
+// unsupported, it falls back to GetAllClients and filters by domain in memory; the
+// fallback ignores bookmark and pageSize and returns every match in a single page
+// with an empty NextBookmark.
+func (c *VPSAContract) QueryClients(ctx contractapi.TransactionContextInterface,
+    bookmark string, pageSize int32, domainFilter string) (*ClientsPage, error) {
+
+    selector := map[string]interface{}{"docType": "client"}
+    if domainFilter != "" {
+        selector["domain"] = domainFilter
+    }
+    queryBytes, err := json.Marshal(map[string]interface{}{"selector": selector})
+    if err != nil {
+        return nil, err
+    }
+
+    iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryBytes), pageSize, bookmark)
+    if err != nil {
+        if richQueryUnsupported(err) {
+            return c.queryClientsFallback(ctx, domainFilter)
+        }
+        return nil, fmt.Errorf("rich query failed: %v", err)
+    }
+    defer iterator.Close()
+
+    var results []*Client
+    for iterator.HasNext() {
+        response, err := iterator.Next()
+        if err != nil {
+            return nil, err
+        }
+        var client Client
+        if err := json.Unmarshal(response.Value, &client); err != nil {
+            return nil, err
+        }
+        results = append(results, &client)
+    }
+
+    return &ClientsPage{
+        Results:        results,
+        NextBookmark:   metadata.Bookmark,
+        FetchedRecords: metadata.FetchedRecordsCount,
+    }, nil
+}
+
+// queryClientsFallback serves QueryClients on backends without rich-query support by
+// routing through the existing list-index-based GetAllClients.
+func (c *VPSAContract) queryClientsFallback(ctx contractapi.TransactionContextInterface, domainFilter string) (*ClientsPage, error) {
+    clients, err := c.GetAllClients(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    if domainFilter != "" {
+        filtered := make([]*Client, 0, len(clients))
+        for _, client := range clients {
+            if client.Domain == domainFilter {
+                filtered = append(filtered, client)
+            }
+        }
+        clients = filtered
+    }
+
+    return &ClientsPage{
+        Results:        clients,
+        NextBookmark:   "",
+        FetchedRecords: int32(len(clients)),
+    }, nil
+}
+
+// QueryLocalModels runs a paginated Mango rich query over local models for round,
+// optionally filtered by status. Falls back to GetLocalModelsByRound when rich
+// queries aren't supported by the backing state database.
+func (c *VPSAContract) QueryLocalModels(ctx contractapi.TransactionContextInterface,
+    round int, statusFilter string, bookmark string, pageSize int32) (*LocalModelsPage, error) {
+
+    selector := map[string]interface{}{"docType": "localModel", "round": round}
+    if statusFilter != "" {
+        selector["status"] = statusFilter
+    }
+    queryBytes, err := json.Marshal(map[string]interface{}{"selector": selector})
+    if err != nil {
+        return nil, err
+    }
+
+    iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryBytes), pageSize, bookmark)
+    if err != nil {
+        if richQueryUnsupported(err) {
+            return c.queryLocalModelsFallback(ctx, round, statusFilter)
+        }
+        return nil, fmt.Errorf("rich query failed: %v", err)
+    }
+    defer iterator.Close()
+
+    var results []*LocalModel
+    for iterator.HasNext() {
+        response, err := iterator.Next()
+        if err != nil {
+            return nil, err
+        }
+        var model LocalModel
+        if err := json.Unmarshal(response.Value, &model); err != nil {
+            return nil, err
+        }
+        results = append(results, &model)
+    }
+
+    return &LocalModelsPage{
+        Results:        results,
+        NextBookmark:   metadata.Bookmark,
+        FetchedRecords: metadata.FetchedRecordsCount,
+    }, nil
+}
+
+// queryLocalModelsFallback serves QueryLocalModels on backends without rich-query
+// support by routing through the existing list-index-based GetLocalModelsByRound.
+func (c *VPSAContract) queryLocalModelsFallback(ctx contractapi.TransactionContextInterface, round int, statusFilter string) (*LocalModelsPage, error) {
+    models, err := c.GetLocalModelsByRound(ctx, round)
+    if err != nil {
+        return nil, err
+    }
+
+    if statusFilter != "" && statusFilter != "submitted" {
+        filtered := make([]*LocalModel, 0, len(models))
+        for _, model := range models {
+            if model.Status == statusFilter {
+                filtered = append(filtered, model)
+            }
+        }
+        models = filtered
+    }
+
+    return &LocalModelsPage{
+        Results:        models,
+        NextBookmark:   "",
+        FetchedRecords: int32(len(models)),
+    }, nil
+}
+
+// QueryTrainingMetrics runs a paginated Mango rich query over training metrics via the
+// docType "metrics" selector. Falls back to GetAllTrainingMetrics when rich queries
+// aren't supported by the backing state database.
+func (c *VPSAContract) QueryTrainingMetrics(ctx contractapi.TransactionContextInterface,
+    bookmark string, pageSize int32) (*MetricsPage, error) {
+
+    selector := map[string]interface{}{"docType": "metrics"}
+    queryBytes, err := json.Marshal(map[string]interface{}{"selector": selector})
+    if err != nil {
+        return nil, err
+    }
+
+    iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryBytes), pageSize, bookmark)
+    if err != nil {
+        if richQueryUnsupported(err) {
+            return c.queryTrainingMetricsFallback(ctx)
+        }
+        return nil, fmt.Errorf("rich query failed: %v", err)
+    }
+    defer iterator.Close()
+
+    var results []*TrainingMetrics
+    for iterator.HasNext() {
+        response, err := iterator.Next()
+        if err != nil {
+            return nil, err
+        }
+        var metrics TrainingMetrics
+        if err := json.Unmarshal(response.Value, &metrics); err != nil {
+            return nil, err
+        }
+        results = append(results, &metrics)
+    }
+
+    return &MetricsPage{
+        Results:        results,
+        NextBookmark:   metadata.Bookmark,
+        FetchedRecords: metadata.FetchedRecordsCount,
+    }, nil
+}
+
+// queryTrainingMetricsFallback serves QueryTrainingMetrics on backends without
+// rich-query support by routing through the existing GetAllTrainingMetrics.
+func (c *VPSAContract) queryTrainingMetricsFallback(ctx contractapi.TransactionContextInterface) (*MetricsPage, error) {
+    metrics, err := c.GetAllTrainingMetrics(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    return &MetricsPage{
+        Results:        metrics,
+        NextBookmark:   "",
+        FetchedRecords: int32(len(metrics)),
+    }, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestRichQueryUnsupported(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want bool
+    }{
+        {"nil error", nil, false},
+        {"LevelDB rejects rich query outright", errors.New("GetQueryResult not supported for leveldb"), true},
+        {"not implemented variant", errors.New("rich queries are not implemented by this state database"), true},
+        {"mixed case still matches", errors.New("Not Supported on this channel"), true},
+        {"genuine CouchDB failure must not be swallowed", errors.New("_design/indexClientDoc not found"), false},
+        {"malformed selector must not be swallowed", errors.New("selector must be a JSON object"), false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := richQueryUnsupported(tt.err); got != tt.want {
+                t.Errorf("richQueryUnsupported(%v) = %v, want %v", tt.err, got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+
+    "github.com/consensys/gnark-crypto/ecc/bls12-381"
+    "github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// blsDST is the domain separation tag used when hashing a cross-channel payload onto
+// the BLS12-381 G1 subgroup, binding signatures to this chaincode's signing context.
+const blsDST = "VPSA-CROSS-CHANNEL-BLS-SIG"
+
+// CrossChannelUpdate is the relayer-verifiable message a source channel exports so its
+// aggregated round can be mixed into a global model trained on another channel.
+// Signature is a BLS12-381 aggregate signature over the payload (with Signature
+// cleared) collected by the relayer from the source channel's endorsement responses.
+type CrossChannelUpdate struct {
+    SourceChannelID   string `json:"sourceChannelID"`
+    SourceRound       int    `json:"sourceRound"`
+    AggregatedWeights string `json:"aggregatedWeights"`
+    Prototypes        string `json:"prototypes"`
+    Signature         string `json:"signature"`
+}
+
+// TrustedChannel is an admin-seeded entry authorizing imports from another channel.
+// Identity rests on two independent checks: possession of the private key matching
+// AggregatePublicKey (proven by the BLS signature), and the relayer submitting the
+// import transaction belonging to RelayerMSPID, the MSP an admin has designated as
+// the one authorized to carry attestations out of that channel. Neither check alone
+// is sufficient: a leaked aggregate key is useless without relayer MSP membership,
+// and relayer MSP membership alone cannot forge the source channel's signature.
+type TrustedChannel struct {
+    ChannelID          string `json:"channelID"`
+    AggregatePublicKey string `json:"aggregatePublicKey"` // hex-encoded BLS12-381 G2 public key
+    RelayerMSPID       string `json:"relayerMSPID"`       // MSP ID the relaying identity must present in ImportCrossChannelUpdate
+    DocType            string `json:"docType"`
+}
+
+// ImportedUpdateRecord audits a successfully imported cross-channel update.
+type ImportedUpdateRecord struct {
+    SourceChannelID string `json:"sourceChannelID"`
+    SourceRound     int    `json:"sourceRound"`
+    ImportedAt      string `json:"importedAt"`
+    DocType         string `json:"docType"`
+}
+
+// canonicalUpdatePayload returns the bytes a CrossChannelUpdate's signature is computed
+// over: the update with Signature cleared, JSON-marshaled.
+func canonicalUpdatePayload(update CrossChannelUpdate) ([]byte, error) {
+    update.Signature = ""
+    return json.Marshal(update)
+}
+
+// verifyBLSSignature checks a hex-encoded, minimal-signature-size BLS12-381 signature
+// (public key in G2, signature in G1) over message using a hex-encoded aggregate
+// public key, following the standard e(H(m), pubKey) == e(sig, g2Generator) pairing
+// check.
+func verifyBLSSignature(publicKeyHex string, signatureHex string, message []byte) (bool, error) {
+    pubKeyBytes, err := hex.DecodeString(publicKeyHex)
+    if err != nil {
+        return false, fmt.Errorf("invalid public key encoding: %v", err)
+    }
+    sigBytes, err := hex.DecodeString(signatureHex)
+    if err != nil {
+        return false, fmt.Errorf("invalid signature encoding: %v", err)
+    }
+
+    var pubKey bls12381.G2Affine
+    if _, err := pubKey.SetBytes(pubKeyBytes); err != nil {
+        return false, fmt.Errorf("invalid public key: %v", err)
+    }
+    var sig bls12381.G1Affine
+    if _, err := sig.SetBytes(sigBytes); err != nil {
+        return false, fmt.Errorf("invalid signature: %v", err)
+    }
+
+    hashedMessage, err := bls12381.HashToG1(message, []byte(blsDST))
+    if err != nil {
+        return false, fmt.Errorf("failed to hash message onto G1: %v", err)
+    }
+
+    _, _, _, g2Gen := bls12381.Generators()
+    var negG2Gen bls12381.G2Affine
+    negG2Gen.Neg(&g2Gen)
+
+    // e(H(m), pubKey) * e(sig, -g2Gen) == 1  <=>  e(H(m), pubKey) == e(sig, g2Gen)
+    return bls12381.PairingCheck(
+        []bls12381.G1Affine{hashedMessage, sig},
+        []bls12381.G2Affine{pubKey, negG2Gen},
+    )
+}
+
+// weightedBlend combines a local and an imported weight vector, with imported
+// contributing importedWeight of the result (0 <= importedWeight <= 1).
+func weightedBlend(local []float64, imported []float64, importedWeight float64) ([]float64, error) {
+    if len(local) != len(imported) {
+        return nil, fmt.Errorf("vector length mismatch: local %d, imported %d", len(local), len(imported))
+    }
+
+    blended := make([]float64, len(local))
+    for i := range local {
+        blended[i] = local[i]*(1-importedWeight) + imported[i]*importedWeight
+    }
+    return blended, nil
+}
+
+// AddTrustedChannel registers another channel's BLS aggregate public key and the MSP
+// authorized to relay its attestations, authorizing cross-channel updates signed by
+// that key and submitted by that MSP to be imported. Admin-only.
+func (c *VPSAContract) AddTrustedChannel(ctx contractapi.TransactionContextInterface,
+    channelID string, aggregatePublicKey string, relayerMSPID string) error {
+
+    admin, err := isAdmin(ctx)
+    if err != nil {
+        return err
+    }
+    if !admin {
+        return fmt.Errorf("only an admin may register a trusted channel")
+    }
+    if relayerMSPID == "" {
+        return fmt.Errorf("relayerMSPID must not be empty")
+    }
+
+    channel := TrustedChannel{
+        ChannelID:          channelID,
+        AggregatePublicKey: aggregatePublicKey,
+        RelayerMSPID:       relayerMSPID,
+        DocType:            "trustedChannel",
+    }
+
+    channelJSON, err := json.Marshal(channel)
+    if err != nil {
+        return err
+    }
+
+    if err := ctx.GetStub().PutState(trustedChannelKey(channelID), channelJSON); err != nil {
+        return err
+    }
+
+    listJSON, err := ctx.GetStub().GetState("trusted-channel-list")
+    if err != nil {
+        return err
+    }
+    var list []string
+    if listJSON != nil {
+        json.Unmarshal(listJSON, &list)
+    }
+    for _, id := range list {
+        if id == channelID {
+            return nil
+        }
+    }
+    list = append(list, channelID)
+    listJSON, _ = json.Marshal(list)
+    return ctx.GetStub().PutState("trusted-channel-list", listJSON)
+}
+
+// GetTrustedChannel retrieves a registered trusted channel's entry.
+func (c *VPSAContract) GetTrustedChannel(ctx contractapi.TransactionContextInterface, channelID string) (*TrustedChannel, error) {
+    channelJSON, err := ctx.GetStub().GetState(trustedChannelKey(channelID))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read trusted channel: %v", err)
+    }
+    if channelJSON == nil {
+        return nil, fmt.Errorf("channel %s is not trusted", channelID)
+    }
+
+    var channel TrustedChannel
+    if err := json.Unmarshal(channelJSON, &channel); err != nil {
+        return nil, err
+    }
+    return &channel, nil
+}
+
+func trustedChannelKey(channelID string) string {
+    return fmt.Sprintf("trusted-channel-%s", channelID)
+}
+
+func importedUpdateKey(sourceChannelID string, sourceRound int) string {
+    return fmt.Sprintf("imported-update-%s-%d", sourceChannelID, sourceRound)
+}
+
+// ExportRoundAttestation serializes the global model produced by round as a
+// CrossChannelUpdate payload (without a signature) for the relayer to sign with a BLS
+// aggregate signature collected from this channel's endorsement responses and deliver
+// to ImportCrossChannelUpdate on another channel.
+func (c *VPSAContract) ExportRoundAttestation(ctx contractapi.TransactionContextInterface, round int) ([]byte, error) {
+    globalModel, err := c.GetGlobalModel(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if globalModel.Round != round {
+        return nil, fmt.Errorf("round %d has not produced the current global model (current round is %d)", round, globalModel.Round)
+    }
+
+    update := CrossChannelUpdate{
+        SourceChannelID:   ctx.GetStub().GetChannelID(),
+        SourceRound:       round,
+        AggregatedWeights: globalModel.Weights,
+        Prototypes:        globalModel.GlobalPrototypes,
+    }
+
+    return json.Marshal(update)
+}
+
+// ImportCrossChannelUpdate verifies a CrossChannelUpdate against the TrustedChannels
+// registry and, if valid and not already imported, blends its weights into the local
+// global model weighted by AggregationConfig.CrossChannelWeight.
+func (c *VPSAContract) ImportCrossChannelUpdate(ctx contractapi.TransactionContextInterface, payload []byte) error {
+    var update CrossChannelUpdate
+    if err := json.Unmarshal(payload, &update); err != nil {
+        return fmt.Errorf("invalid cross-channel update: %v", err)
+    }
+
+    trusted, err := c.GetTrustedChannel(ctx, update.SourceChannelID)
+    if err != nil {
+        return err
+    }
+
+    submitterMSPID, err := ctx.GetClientIdentity().GetMSPID()
+    if err != nil {
+        return fmt.Errorf("failed to read submitter MSP ID: %v", err)
+    }
+    if submitterMSPID != trusted.RelayerMSPID {
+        return fmt.Errorf("channel %s may only be relayed by MSP %s, got %s", update.SourceChannelID, trusted.RelayerMSPID, submitterMSPID)
+    }
+
+    replayKey := importedUpdateKey(update.SourceChannelID, update.SourceRound)
+    existing, err := ctx.GetStub().GetState(replayKey)
+    if err != nil {
+        return err
+    }
+    if existing != nil {
+        return fmt.Errorf("round %d from channel %s was already imported", update.SourceRound, update.SourceChannelID)
+    }
+
+    message, err := canonicalUpdatePayload(update)
+    if err != nil {
+        return err
+    }
+    ok, err := verifyBLSSignature(trusted.AggregatePublicKey, update.Signature, message)
+    if err != nil {
+        return fmt.Errorf("failed to verify cross-channel signature: %v", err)
+    }
+    if !ok {
+        return fmt.Errorf("cross-channel signature from %s does not verify", update.SourceChannelID)
+    }
+
+    config, err := c.GetAggregationConfig(ctx)
+    if err != nil {
+        return err
+    }
+
+    globalModel, err := c.GetGlobalModel(ctx)
+    if err != nil {
+        return err
+    }
+
+    localVector, err := decodeWeightVector(globalModel.Weights)
+    if err != nil {
+        return fmt.Errorf("failed to decode local global weights: %v", err)
+    }
+    importedVector, err := decodeWeightVector(update.AggregatedWeights)
+    if err != nil {
+        return fmt.Errorf("failed to decode imported weights: %v", err)
+    }
+    blended, err := weightedBlend(localVector, importedVector, config.CrossChannelWeight)
+    if err != nil {
+        return err
+    }
+
+    timestamp, err := getTxTimestamp(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to get transaction timestamp: %v", err)
+    }
+
+    blendedWeights, err := encodeWeightVector(blended)
+    if err != nil {
+        return err
+    }
+    globalModel.Weights = blendedWeights
+    globalModel.Version++
+    globalModel.Timestamp = timestamp
+
+    globalJSON, err := json.Marshal(globalModel)
+    if err != nil {
+        return err
+    }
+    if err := ctx.GetStub().PutState("vpsa-global-model", globalJSON); err != nil {
+        return err
+    }
+
+    record := ImportedUpdateRecord{
+        SourceChannelID: update.SourceChannelID,
+        SourceRound:     update.SourceRound,
+        ImportedAt:      timestamp,
+        DocType:         "importedUpdate",
+    }
+    recordJSON, err := json.Marshal(record)
+    if err != nil {
+        return err
+    }
+    if err := ctx.GetStub().PutState(replayKey, recordJSON); err != nil {
+        return err
+    }
+
+    listJSON, err := ctx.GetStub().GetState("imported-update-list")
+    if err != nil {
+        return err
+    }
+    var list []string
+    if listJSON != nil {
+        json.Unmarshal(listJSON, &list)
+    }
+    list = append(list, replayKey)
+    listJSON, _ = json.Marshal(list)
+    return ctx.GetStub().PutState("imported-update-list", listJSON)
+}
+
+// ListImportedUpdates returns every cross-channel update imported so far, for
+// observability and audit.
+func (c *VPSAContract) ListImportedUpdates(ctx contractapi.TransactionContextInterface) ([]*ImportedUpdateRecord, error) {
+    listJSON, err := ctx.GetStub().GetState("imported-update-list")
+    if err != nil {
+        return nil, err
+    }
+    var list []string
+    if listJSON != nil {
+        json.Unmarshal(listJSON, &list)
+    }
+
+    var records []*ImportedUpdateRecord
+    for _, key := range list {
+        recordJSON, err := ctx.GetStub().GetState(key)
+        if err != nil || recordJSON == nil {
+            continue
+        }
+        var record ImportedUpdateRecord
+        if err := json.Unmarshal(recordJSON, &record); err != nil {
+            continue
+        }
+        records = append(records, &record)
+    }
+
+    return records, nil
+}
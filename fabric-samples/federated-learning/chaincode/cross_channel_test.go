@@ -0,0 +1,64 @@
+package main
+
+import (
+    "encoding/hex"
+    "math/big"
+    "testing"
+
+    "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+func TestVerifyBLSSignatureAcceptsGenuineSignature(t *testing.T) {
+    sk, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+    _, _, _, g2Gen := bls12381.Generators()
+    var pubKey bls12381.G2Affine
+    pubKey.ScalarMultiplication(&g2Gen, sk)
+
+    message := []byte(`{"sourceChannelID":"channel2","sourceRound":1}`)
+    hashedMessage, err := bls12381.HashToG1(message, []byte(blsDST))
+    if err != nil {
+        t.Fatalf("HashToG1 returned error: %v", err)
+    }
+    var sig bls12381.G1Affine
+    sig.ScalarMultiplication(&hashedMessage, sk)
+
+    pubKeyBytes := pubKey.Bytes()
+    sigBytes := sig.Bytes()
+
+    ok, err := verifyBLSSignature(hex.EncodeToString(pubKeyBytes[:]), hex.EncodeToString(sigBytes[:]), message)
+    if err != nil {
+        t.Fatalf("verifyBLSSignature returned error: %v", err)
+    }
+    if !ok {
+        t.Fatal("verifyBLSSignature rejected a genuinely signed message")
+    }
+}
+
+func TestVerifyBLSSignatureRejectsWrongKey(t *testing.T) {
+    sk, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+    otherSK, _ := new(big.Int).SetString("987654321098765432109876543210", 10)
+
+    _, _, _, g2Gen := bls12381.Generators()
+    var wrongPubKey bls12381.G2Affine
+    wrongPubKey.ScalarMultiplication(&g2Gen, otherSK)
+
+    message := []byte(`{"sourceChannelID":"channel2","sourceRound":1}`)
+    hashedMessage, err := bls12381.HashToG1(message, []byte(blsDST))
+    if err != nil {
+        t.Fatalf("HashToG1 returned error: %v", err)
+    }
+    var sig bls12381.G1Affine
+    sig.ScalarMultiplication(&hashedMessage, sk)
+
+    wrongPubKeyBytes := wrongPubKey.Bytes()
+    sigBytes := sig.Bytes()
+
+    ok, err := verifyBLSSignature(hex.EncodeToString(wrongPubKeyBytes[:]), hex.EncodeToString(sigBytes[:]), message)
+    if err != nil {
+        t.Fatalf("verifyBLSSignature returned error: %v", err)
+    }
+    if ok {
+        t.Fatal("verifyBLSSignature accepted a signature verified against the wrong public key")
+    }
+}
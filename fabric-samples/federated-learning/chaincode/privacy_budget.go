@@ -0,0 +1,210 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+
+    "github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ErrPrivacyBudgetExceeded is returned when a submission's differential-privacy cost
+// would push a client's cumulative epsilon past its configured total.
+var ErrPrivacyBudgetExceeded = errors.New("submission would exceed client's differential-privacy budget")
+
+// rdpOrders is the fixed grid of Renyi orders the Gaussian mechanism's RDP-to-DP
+// conversion is minimized over.
+var rdpOrders = []float64{2, 4, 8, 16, 32, 64}
+
+// PrivacyBudget tracks a client's cumulative differential-privacy loss across rounds.
+type PrivacyBudget struct {
+    ClientID        string  `json:"clientID"`
+    EpsilonTotal    float64 `json:"epsilonTotal"`
+    Delta           float64 `json:"delta"`
+    EpsilonSpent    float64 `json:"epsilonSpent"`
+    Mechanism       string  `json:"mechanism"` // "gaussian" or "laplace"
+    NoiseMultiplier float64 `json:"noiseMultiplier"` // gaussian noise multiplier (sigma), or laplace noise scale (b)
+    SamplingRate    float64 `json:"samplingRate"` // gaussian sub-sampling rate; unused for laplace
+    DocType         string  `json:"docType"`
+}
+
+func privacyBudgetKey(clientID string) string {
+    return fmt.Sprintf("privacy-budget-%s", clientID)
+}
+
+func roundEpsilonKey(round int) string {
+    return fmt.Sprintf("round-%d-epsilon", round)
+}
+
+// gaussianRDPEpsilon computes the (epsilon, delta) cost of one round of the Gaussian
+// mechanism with the given noise multiplier and sub-sampling rate, converting the
+// analytical RDP bound at each order in rdpOrders to (epsilon, delta)-DP and taking
+// the minimum, as is standard for a moments-accountant-style analysis.
+func gaussianRDPEpsilon(noiseMultiplier float64, samplingRate float64, delta float64) float64 {
+    best := math.Inf(1)
+    for _, alpha := range rdpOrders {
+        rdp := alpha * samplingRate * samplingRate / (2 * noiseMultiplier * noiseMultiplier)
+        epsilon := rdp + math.Log(1/delta)/(alpha-1)
+        if epsilon < best {
+            best = epsilon
+        }
+    }
+    return best
+}
+
+// SetPrivacyBudget creates or reconfigures a client's privacy budget. Admin-only.
+// Reconfiguring an existing budget preserves its accumulated epsilonSpent.
+func (c *VPSAContract) SetPrivacyBudget(ctx contractapi.TransactionContextInterface,
+    clientID string, epsilonTotal float64, delta float64, mechanism string,
+    noiseMultiplier float64, samplingRate float64) error {
+
+    admin, err := isAdmin(ctx)
+    if err != nil {
+        return err
+    }
+    if !admin {
+        return fmt.Errorf("only an admin may set a privacy budget")
+    }
+
+    if mechanism != "gaussian" && mechanism != "laplace" {
+        return fmt.Errorf("unsupported mechanism: %s", mechanism)
+    }
+
+    epsilonSpent := 0.0
+    if existing, err := c.GetPrivacyBudget(ctx, clientID); err == nil {
+        epsilonSpent = existing.EpsilonSpent
+    }
+
+    budget := PrivacyBudget{
+        ClientID:        clientID,
+        EpsilonTotal:    epsilonTotal,
+        Delta:           delta,
+        EpsilonSpent:    epsilonSpent,
+        Mechanism:       mechanism,
+        NoiseMultiplier: noiseMultiplier,
+        SamplingRate:    samplingRate,
+        DocType:         "privacyBudget",
+    }
+
+    budgetJSON, err := json.Marshal(budget)
+    if err != nil {
+        return err
+    }
+
+    return ctx.GetStub().PutState(privacyBudgetKey(clientID), budgetJSON)
+}
+
+// GetPrivacyBudget retrieves a client's privacy budget.
+func (c *VPSAContract) GetPrivacyBudget(ctx contractapi.TransactionContextInterface, clientID string) (*PrivacyBudget, error) {
+    budgetJSON, err := ctx.GetStub().GetState(privacyBudgetKey(clientID))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read privacy budget: %v", err)
+    }
+    if budgetJSON == nil {
+        return nil, fmt.Errorf("no privacy budget configured for client %s", clientID)
+    }
+
+    var budget PrivacyBudget
+    if err := json.Unmarshal(budgetJSON, &budget); err != nil {
+        return nil, err
+    }
+    return &budget, nil
+}
+
+// chargePrivacyBudget computes the epsilon cost of this round's submission for
+// clientID, rejects it if that would exceed the client's total, and otherwise debits
+// the budget and records the per-round increment under round for later auditing. It
+// returns the actual amount charged so the caller can persist that figure — rather
+// than the client's claim — as the on-chain record of this round's cost.
+// epsilonThisRound is the client's claimed cost, used only as a sanity check that the
+// client acknowledges a positive privacy cost; it never feeds the charged amount, so
+// a client cannot under- or overstate what regulators see on-chain. sensitivity is the
+// client's claimed query sensitivity; for the Laplace mechanism the increment is that
+// claimed sensitivity divided by the budget's configured noise scale, so the charge
+// scales with the sensitivity the client supplies.
+func (c *VPSAContract) chargePrivacyBudget(ctx contractapi.TransactionContextInterface,
+    clientID string, round int, epsilonThisRound float64, sensitivity float64) (float64, error) {
+
+    if epsilonThisRound <= 0 {
+        return 0, fmt.Errorf("epsilonThisRound must be positive, got %.6f", epsilonThisRound)
+    }
+    if sensitivity <= 0 {
+        return 0, fmt.Errorf("sensitivity must be positive, got %.6f", sensitivity)
+    }
+
+    budget, err := c.GetPrivacyBudget(ctx, clientID)
+    if err != nil {
+        return 0, err
+    }
+
+    var increment float64
+    switch budget.Mechanism {
+    case "gaussian":
+        increment = gaussianRDPEpsilon(budget.NoiseMultiplier, budget.SamplingRate, budget.Delta)
+    default: // "laplace": exact epsilon = client-supplied sensitivity / server-held noise scale
+        if budget.NoiseMultiplier <= 0 {
+            return 0, fmt.Errorf("privacy budget for client %s has no configured Laplace noise scale", clientID)
+        }
+        increment = sensitivity / budget.NoiseMultiplier
+    }
+
+    if budget.EpsilonSpent+increment > budget.EpsilonTotal {
+        return 0, fmt.Errorf("%w: client %s has spent %.4f of %.4f, this round costs %.4f",
+            ErrPrivacyBudgetExceeded, clientID, budget.EpsilonSpent, budget.EpsilonTotal, increment)
+    }
+
+    budget.EpsilonSpent += increment
+    budgetJSON, err := json.Marshal(budget)
+    if err != nil {
+        return 0, err
+    }
+    if err := ctx.GetStub().PutState(privacyBudgetKey(clientID), budgetJSON); err != nil {
+        return 0, err
+    }
+
+    if err := c.recordRoundEpsilon(ctx, round, clientID, increment); err != nil {
+        return 0, err
+    }
+    return increment, nil
+}
+
+// recordRoundEpsilon accumulates per-client epsilon increments for round so they can
+// be copied into that round's TrainingMetrics once it is aggregated.
+func (c *VPSAContract) recordRoundEpsilon(ctx contractapi.TransactionContextInterface, round int, clientID string, increment float64) error {
+    key := roundEpsilonKey(round)
+    existingJSON, err := ctx.GetStub().GetState(key)
+    if err != nil {
+        return err
+    }
+
+    spent := map[string]float64{}
+    if existingJSON != nil {
+        if err := json.Unmarshal(existingJSON, &spent); err != nil {
+            return err
+        }
+    }
+    spent[clientID] += increment
+
+    spentJSON, err := json.Marshal(spent)
+    if err != nil {
+        return err
+    }
+    return ctx.GetStub().PutState(key, spentJSON)
+}
+
+// getRoundEpsilon retrieves the per-client epsilon increments recorded for round.
+func (c *VPSAContract) getRoundEpsilon(ctx contractapi.TransactionContextInterface, round int) (map[string]float64, error) {
+    existingJSON, err := ctx.GetStub().GetState(roundEpsilonKey(round))
+    if err != nil {
+        return nil, err
+    }
+
+    spent := map[string]float64{}
+    if existingJSON != nil {
+        if err := json.Unmarshal(existingJSON, &spent); err != nil {
+            return nil, err
+        }
+    }
+    return spent, nil
+}
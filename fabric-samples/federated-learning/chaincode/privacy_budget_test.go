@@ -0,0 +1,120 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "math"
+    "testing"
+
+    "github.com/hyperledger/fabric-chaincode-go/shimtest"
+    "github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// newPrivacyBudgetContext returns a VPSAContract paired with a transaction context
+// backed by an in-memory MockStub, for exercising ledger-touching functions without a
+// real peer.
+func newPrivacyBudgetContext() (*VPSAContract, contractapi.TransactionContextInterface) {
+    stub := shimtest.NewMockStub("vpsa", nil)
+    stub.MockTransactionStart("tx1")
+    ctx := &contractapi.TransactionContext{}
+    ctx.SetStub(stub)
+    return &VPSAContract{}, ctx
+}
+
+// putPrivacyBudget seeds a client's privacy budget directly on the mock ledger,
+// bypassing SetPrivacyBudget's admin check so tests can focus on chargePrivacyBudget.
+func putPrivacyBudget(t *testing.T, ctx contractapi.TransactionContextInterface, budget PrivacyBudget) {
+    t.Helper()
+    budget.DocType = "privacyBudget"
+    budgetJSON, err := json.Marshal(budget)
+    if err != nil {
+        t.Fatalf("failed to marshal privacy budget: %v", err)
+    }
+    if err := ctx.GetStub().PutState(privacyBudgetKey(budget.ClientID), budgetJSON); err != nil {
+        t.Fatalf("failed to seed privacy budget: %v", err)
+    }
+}
+
+func TestGaussianRDPEpsilonMatchesReferenceValue(t *testing.T) {
+    // sigma=1.0, q=0.01, delta=1e-5: the moments-accountant minimum over
+    // rdpOrders lands at alpha=64, independently computed as a reference value.
+    got := gaussianRDPEpsilon(1.0, 0.01, 1e-5)
+    want := 0.1859448486503211
+
+    if math.Abs(got-want) > 1e-9 {
+        t.Errorf("gaussianRDPEpsilon(1.0, 0.01, 1e-5) = %.10f, want %.10f", got, want)
+    }
+}
+
+func TestGaussianRDPEpsilonDecreasesWithNoise(t *testing.T) {
+    low := gaussianRDPEpsilon(1.0, 0.01, 1e-5)
+    high := gaussianRDPEpsilon(4.0, 0.01, 1e-5)
+
+    if !(high < low) {
+        t.Errorf("expected larger noise multiplier to cost less epsilon: sigma=1.0 -> %v, sigma=4.0 -> %v", low, high)
+    }
+}
+
+func TestChargePrivacyBudgetLaplaceChargesSensitivityOverNoiseScale(t *testing.T) {
+    contract, ctx := newPrivacyBudgetContext()
+
+    putPrivacyBudget(t, ctx, PrivacyBudget{
+        ClientID:        "client-1",
+        EpsilonTotal:    10,
+        Delta:           1e-5,
+        Mechanism:       "laplace",
+        NoiseMultiplier: 2.0,
+    })
+
+    charged, err := contract.chargePrivacyBudget(ctx, "client-1", 0, 0.5, 4.0)
+    if err != nil {
+        t.Fatalf("chargePrivacyBudget returned error: %v", err)
+    }
+
+    want := 2.0 // sensitivity 4.0 / noise scale 2.0
+    if charged != want {
+        t.Errorf("charged = %v, want %v", charged, want)
+    }
+
+    budget, err := contract.GetPrivacyBudget(ctx, "client-1")
+    if err != nil {
+        t.Fatalf("GetPrivacyBudget returned error: %v", err)
+    }
+    if budget.EpsilonSpent != want {
+        t.Errorf("budget.EpsilonSpent = %v, want %v", budget.EpsilonSpent, want)
+    }
+}
+
+func TestChargePrivacyBudgetRejectsWhenBudgetExceeded(t *testing.T) {
+    contract, ctx := newPrivacyBudgetContext()
+
+    putPrivacyBudget(t, ctx, PrivacyBudget{
+        ClientID:        "client-1",
+        EpsilonTotal:    1.0,
+        Delta:           1e-5,
+        Mechanism:       "laplace",
+        NoiseMultiplier: 1.0,
+    })
+
+    // First charge of 0.8 (sensitivity/noiseScale = 0.8/1.0) fits within the 1.0 total.
+    if _, err := contract.chargePrivacyBudget(ctx, "client-1", 0, 0.1, 0.8); err != nil {
+        t.Fatalf("first charge should have succeeded, got error: %v", err)
+    }
+
+    // A second charge of 0.8 would push spent to 1.6, past the 1.0 total.
+    _, err := contract.chargePrivacyBudget(ctx, "client-1", 1, 0.1, 0.8)
+    if err == nil {
+        t.Fatal("expected chargePrivacyBudget to reject a submission exceeding the remaining budget, got nil")
+    }
+    if !errors.Is(err, ErrPrivacyBudgetExceeded) {
+        t.Errorf("expected error to wrap ErrPrivacyBudgetExceeded, got: %v", err)
+    }
+
+    budget, err := contract.GetPrivacyBudget(ctx, "client-1")
+    if err != nil {
+        t.Fatalf("GetPrivacyBudget returned error: %v", err)
+    }
+    if budget.EpsilonSpent != 0.8 {
+        t.Errorf("a rejected charge must not debit the budget: EpsilonSpent = %v, want 0.8", budget.EpsilonSpent)
+    }
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+    "crypto/sha256"
     "encoding/json"
     "fmt"
     "time"
@@ -21,6 +22,7 @@ type Client struct {
     LastUpdate      string    `json:"lastUpdate"`
     DatasetSize     int       `json:"datasetSize"`
     ModelAccuracy   float64   `json:"modelAccuracy"`
+    ReputationStrikes int     `json:"reputationStrikes"` // incremented each time an aggregation round excludes this client
     DocType         string    `json:"docType"` // Added for type identification
 }
 
@@ -37,6 +39,11 @@ type LocalModel struct {
     Loss            float64            `json:"loss"`
     AlignmentLoss   float64            `json:"alignmentLoss"`
     DataSize        int                `json:"dataSize"`
+    Proof           string             `json:"proof"`
+    PublicInputs    PublicInputs       `json:"publicInputs"`
+    VerifyingKeyFingerprint string      `json:"verifyingKeyFingerprint"` // sha256 of the Groth16 verifying key the proof checked against; differs across key rotations
+    EpsilonThisRound float64           `json:"epsilonThisRound"` // server-charged cost, not the client's claim; TrainingMetrics.EpsilonSpent sums these per client for this round
+    Sensitivity     float64            `json:"sensitivity"`
     Timestamp       string             `json:"timestamp"`
     Status          string             `json:"status"`
     DocType         string             `json:"docType"` // Added for type identification
@@ -58,6 +65,7 @@ type GlobalModel struct {
     TargetClients   int                `json:"targetClients"`
     Timestamp       string             `json:"timestamp"`
     Status          string             `json:"status"`
+    DocType         string             `json:"docType"`
 }
 
 // AggregationConfig stores configuration for model aggregation
@@ -69,10 +77,26 @@ type AggregationConfig struct {
     TargetWeight        float64   `json:"targetWeight"`
     AlignmentWeight     float64   `json:"alignmentWeight"`
     ConvergenceThreshold float64  `json:"convergenceThreshold"`
+    AggregationMode     string    `json:"aggregationMode"` // fedavg, krum, multi-krum, trimmed-mean, median
+    ByzantineTolerance  int       `json:"byzantineTolerance"` // max assumed malicious clients (f)
+    CrossChannelWeight  float64   `json:"crossChannelWeight"` // share given to an imported cross-channel update when blending
     CurrentRound        int       `json:"currentRound"`
     LastUpdated         string    `json:"lastUpdated"`
 }
 
+// AggregationDecision records how a round's aggregate was derived: which clients'
+// updates were included or excluded as likely-Byzantine, and their robustness scores.
+type AggregationDecision struct {
+    DecisionID        string             `json:"decisionID"`
+    Round             int                `json:"round"`
+    Mode              string             `json:"mode"`
+    IncludedClientIDs []string           `json:"includedClientIDs"`
+    ExcludedClientIDs []string           `json:"excludedClientIDs"`
+    Scores            map[string]float64 `json:"scores"` // clientID -> robustness score, where the mode computes one
+    Timestamp         string             `json:"timestamp"`
+    DocType           string             `json:"docType"`
+}
+
 // TrainingMetrics stores per-round training metrics
 type TrainingMetrics struct {
     MetricID        string    `json:"metricID"`
@@ -83,7 +107,10 @@ type TrainingMetrics struct {
     TargetAccuracy  float64   `json:"targetAccuracy"`
     AlignmentScore  float64   `json:"alignmentScore"`
     NumParticipants int       `json:"numParticipants"`
+    AggregationVerifyingKeyFingerprint string `json:"aggregationVerifyingKeyFingerprint"` // sha256 of the Groth16 verifying key the aggregation proof checked against; differs across key rotations
+    EpsilonSpent    map[string]float64 `json:"epsilonSpent"` // clientID -> epsilon charged this round
     Timestamp       string    `json:"timestamp"`
+    DocType         string    `json:"docType"`
 }
 
 // getTxTimestamp retrieves the transaction timestamp
@@ -95,6 +122,15 @@ func getTxTimestamp(ctx contractapi.TransactionContextInterface) (string, error)
     return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC().Format(time.RFC3339Nano), nil
 }
 
+// isAdmin checks whether the submitting identity carries the "admin" role attribute.
+func isAdmin(ctx contractapi.TransactionContextInterface) (bool, error) {
+    value, found, err := ctx.GetClientIdentity().GetAttributeValue("role")
+    if err != nil {
+        return false, fmt.Errorf("failed to read client identity attributes: %v", err)
+    }
+    return found && value == "admin", nil
+}
+
 // InitLedger initializes the chaincode
 func (c *VPSAContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
     timestamp, err := getTxTimestamp(ctx)
@@ -117,6 +153,7 @@ func (c *VPSAContract) InitLedger(ctx contractapi.TransactionContextInterface) e
         TargetClients:    0,
         Timestamp:        timestamp,
         Status:           "initialized",
+        DocType:          "globalModel",
     }
 
     globalJSON, err := json.Marshal(globalModel)
@@ -137,6 +174,9 @@ func (c *VPSAContract) InitLedger(ctx contractapi.TransactionContextInterface) e
         TargetWeight:         0.4,
         AlignmentWeight:      0.1,
         ConvergenceThreshold: 0.001,
+        AggregationMode:      "fedavg",
+        ByzantineTolerance:   0,
+        CrossChannelWeight:   0.0,
         CurrentRound:         0,
         LastUpdated:          timestamp,
     }
@@ -263,11 +303,13 @@ func (c *VPSAContract) GetAllClients(ctx contractapi.TransactionContextInterface
     return clients, nil
 }
 
-// SubmitLocalModel allows a client to submit their locally trained model
+// SubmitLocalModel allows a client to submit their locally trained model, proven via a
+// zk-SNARK proof that it was honestly derived from the claimed dataset and prior global model.
 func (c *VPSAContract) SubmitLocalModel(ctx contractapi.TransactionContextInterface,
-    modelID string, clientID string, weights string, latentFeatures string, 
-    prototypes string, accuracy float64, loss float64, alignmentLoss float64, 
-    dataSize int) error {
+    modelID string, clientID string, weights string, latentFeatures string,
+    prototypes string, accuracy float64, loss float64, alignmentLoss float64,
+    dataSize int, proof string, dataRootHash string, weightCommitment string,
+    epsilonThisRound float64, sensitivity float64) error {
 
     client, err := c.GetClient(ctx, clientID)
     if err != nil {
@@ -283,26 +325,53 @@ func (c *VPSAContract) SubmitLocalModel(ctx contractapi.TransactionContextInterf
         return err
     }
 
+    globalModel, err := c.GetGlobalModel(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to get global model: %v", err)
+    }
+
+    epsilonCharged, err := c.chargePrivacyBudget(ctx, clientID, config.CurrentRound, epsilonThisRound, sensitivity)
+    if err != nil {
+        return err
+    }
+
+    publicInputs := PublicInputs{
+        DataRootHash:           dataRootHash,
+        PriorGlobalWeightsHash: fmt.Sprintf("%x", sha256.Sum256([]byte(globalModel.Weights))),
+        ClaimedDataSize:        dataSize,
+        WeightCommitment:       weightCommitment,
+    }
+
+    verifyingKeyFingerprint, err := c.verifyProof(ctx, proof, publicInputs)
+    if err != nil {
+        return err
+    }
+
     timestamp, err := getTxTimestamp(ctx)
     if err != nil {
         return fmt.Errorf("failed to get transaction timestamp: %v", err)
     }
 
     localModel := LocalModel{
-        ModelID:        modelID,
-        ClientID:       clientID,
-        Round:          config.CurrentRound,
-        Domain:         client.Domain,
-        Weights:        weights,
-        LatentFeatures: latentFeatures,
-        Prototypes:     prototypes,
-        Accuracy:       accuracy,
-        Loss:           loss,
-        AlignmentLoss:  alignmentLoss,
-        DataSize:       dataSize,
-        Timestamp:      timestamp,
-        Status:         "submitted",
-        DocType:        "localModel",
+        ModelID:                 modelID,
+        ClientID:                clientID,
+        Round:                   config.CurrentRound,
+        Domain:                  client.Domain,
+        Weights:                 weights,
+        LatentFeatures:          latentFeatures,
+        Prototypes:              prototypes,
+        Accuracy:                accuracy,
+        Loss:                    loss,
+        AlignmentLoss:           alignmentLoss,
+        DataSize:                dataSize,
+        Proof:                   proof,
+        PublicInputs:            publicInputs,
+        VerifyingKeyFingerprint: verifyingKeyFingerprint,
+        EpsilonThisRound:        epsilonCharged,
+        Sensitivity:             sensitivity,
+        Timestamp:               timestamp,
+        Status:                  "submitted",
+        DocType:                 "localModel",
     }
 
     modelJSON, err := json.Marshal(localModel)
@@ -384,10 +453,215 @@ func (c *VPSAContract) GetLocalModelsByRound(ctx contractapi.TransactionContextI
     return models, nil
 }
 
-// AggregateModels performs federated aggregation
-func (c *VPSAContract) AggregateModels(ctx contractapi.TransactionContextInterface,
+// SetVerifyingKey stores the Groth16 verifying key used to validate proofs for the
+// current training campaign. Admin-only so keys can be rotated between campaigns.
+func (c *VPSAContract) SetVerifyingKey(ctx contractapi.TransactionContextInterface, verifyingKeyJSON string) error {
+    admin, err := isAdmin(ctx)
+    if err != nil {
+        return err
+    }
+    if !admin {
+        return fmt.Errorf("only an admin may set the verifying key")
+    }
+
+    var vk Groth16VerifyingKey
+    if err := json.Unmarshal([]byte(verifyingKeyJSON), &vk); err != nil {
+        return fmt.Errorf("invalid verifying key: %v", err)
+    }
+
+    return ctx.GetStub().PutState("vpsa-vk", []byte(verifyingKeyJSON))
+}
+
+// GetVerifyingKey retrieves the currently configured Groth16 verifying key.
+func (c *VPSAContract) GetVerifyingKey(ctx contractapi.TransactionContextInterface) (string, error) {
+    vkJSON, err := ctx.GetStub().GetState("vpsa-vk")
+    if err != nil {
+        return "", fmt.Errorf("failed to read verifying key: %v", err)
+    }
+    if vkJSON == nil {
+        return "", ErrVerifyingKeyNotSet
+    }
+    return string(vkJSON), nil
+}
+
+// verifyProof fetches the configured verifying key and checks proof against
+// publicInputs, returning a fingerprint (sha256) of the verifying key used so callers
+// can record which key rotation a submission was checked against.
+func (c *VPSAContract) verifyProof(ctx contractapi.TransactionContextInterface, proof string, publicInputs PublicInputs) (string, error) {
+    verifyingKeyJSON, err := c.GetVerifyingKey(ctx)
+    if err != nil {
+        return "", err
+    }
+
+    verifier := &Groth16Verifier{}
+    ok, err := verifier.Verify(proof, publicInputs, verifyingKeyJSON)
+    if err != nil {
+        return "", fmt.Errorf("%v: %w", ErrProofVerificationFailed, err)
+    }
+    if !ok {
+        return "", ErrProofVerificationFailed
+    }
+    return fmt.Sprintf("%x", sha256.Sum256([]byte(verifyingKeyJSON))), nil
+}
+
+// ProposeAggregation collects the submitted local models for round, computes the
+// aggregate weight vector on-chain according to config.AggregationMode, and commits it
+// as the new global model. Because the aggregate is derived deterministically from
+// state every endorsing peer already has, no caller-supplied weights are trusted.
+func (c *VPSAContract) ProposeAggregation(ctx contractapi.TransactionContextInterface,
+    round int, aggregatedPrototypes string, globalAccuracy float64, globalLoss float64,
+    alignmentScore float64, aggregationProof string) error {
+
+    config, err := c.GetAggregationConfig(ctx)
+    if err != nil {
+        return err
+    }
+
+    localModels, err := c.GetLocalModelsByRound(ctx, round)
+    if err != nil {
+        return err
+    }
+    if len(localModels) < config.MinClients {
+        return fmt.Errorf("round %d has %d submitted models, fewer than the %d required", round, len(localModels), config.MinClients)
+    }
+
+    modelIDs := make([]string, len(localModels))
+    vectors := make([][]float64, len(localModels))
+    dataSizes := make([]int, len(localModels))
+    for i, model := range localModels {
+        modelIDs[i] = model.ModelID
+        dataSizes[i] = model.DataSize
+        vector, err := decodeWeightVector(model.Weights)
+        if err != nil {
+            return fmt.Errorf("failed to decode weights for model %s: %v", model.ModelID, err)
+        }
+        vectors[i] = vector
+    }
+
+    decision := AggregationDecision{
+        DecisionID: fmt.Sprintf("decision-round-%d", round),
+        Round:      round,
+        Mode:       config.AggregationMode,
+        Scores:     map[string]float64{},
+        DocType:    "aggregationDecision",
+    }
+
+    var aggregated []float64
+    switch config.AggregationMode {
+    case "krum":
+        selected, scores, err := krum(vectors, config.ByzantineTolerance)
+        if err != nil {
+            return fmt.Errorf("krum aggregation failed: %v", err)
+        }
+        aggregated = vectors[selected]
+        for i, model := range localModels {
+            decision.Scores[model.ClientID] = roundFloat(scores[i], 6)
+            if i == selected {
+                decision.IncludedClientIDs = append(decision.IncludedClientIDs, model.ClientID)
+            } else {
+                decision.ExcludedClientIDs = append(decision.ExcludedClientIDs, model.ClientID)
+            }
+        }
+
+    case "multi-krum":
+        m := len(vectors) - config.ByzantineTolerance
+        selected, scores, err := multiKrum(vectors, config.ByzantineTolerance, m)
+        if err != nil {
+            return fmt.Errorf("multi-krum aggregation failed: %v", err)
+        }
+        selectedSet := make(map[int]bool, len(selected))
+        for _, idx := range selected {
+            selectedSet[idx] = true
+        }
+        topVectors := make([][]float64, len(selected))
+        topDataSizes := make([]int, len(selected))
+        for i, idx := range selected {
+            topVectors[i] = vectors[idx]
+            topDataSizes[i] = dataSizes[idx]
+        }
+        aggregated, err = fedAvg(topVectors, topDataSizes)
+        if err != nil {
+            return fmt.Errorf("multi-krum averaging failed: %v", err)
+        }
+        for i, model := range localModels {
+            decision.Scores[model.ClientID] = roundFloat(scores[i], 6)
+            if selectedSet[i] {
+                decision.IncludedClientIDs = append(decision.IncludedClientIDs, model.ClientID)
+            } else {
+                decision.ExcludedClientIDs = append(decision.ExcludedClientIDs, model.ClientID)
+            }
+        }
+
+    case "trimmed-mean":
+        aggregated, err = trimmedMean(vectors, config.ByzantineTolerance)
+        if err != nil {
+            return fmt.Errorf("trimmed-mean aggregation failed: %v", err)
+        }
+        for _, model := range localModels {
+            decision.IncludedClientIDs = append(decision.IncludedClientIDs, model.ClientID)
+        }
+
+    case "median":
+        aggregated, err = coordinateMedian(vectors)
+        if err != nil {
+            return fmt.Errorf("median aggregation failed: %v", err)
+        }
+        for _, model := range localModels {
+            decision.IncludedClientIDs = append(decision.IncludedClientIDs, model.ClientID)
+        }
+
+    default: // "fedavg"
+        aggregated, err = fedAvg(vectors, dataSizes)
+        if err != nil {
+            return fmt.Errorf("fedavg aggregation failed: %v", err)
+        }
+        for _, model := range localModels {
+            decision.IncludedClientIDs = append(decision.IncludedClientIDs, model.ClientID)
+        }
+    }
+
+    timestamp, err := getTxTimestamp(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to get transaction timestamp: %v", err)
+    }
+    decision.Timestamp = timestamp
+
+    decisionJSON, err := json.Marshal(decision)
+    if err != nil {
+        return err
+    }
+    if err := ctx.GetStub().PutState(decision.DecisionID, decisionJSON); err != nil {
+        return err
+    }
+
+    for _, excludedID := range decision.ExcludedClientIDs {
+        client, err := c.GetClient(ctx, excludedID)
+        if err != nil {
+            continue
+        }
+        client.ReputationStrikes++
+        clientJSON, err := json.Marshal(client)
+        if err != nil {
+            return err
+        }
+        if err := ctx.GetStub().PutState(excludedID, clientJSON); err != nil {
+            return err
+        }
+    }
+
+    aggregatedWeights, err := encodeWeightVector(aggregated)
+    if err != nil {
+        return err
+    }
+
+    return c.commitAggregation(ctx, modelIDs, aggregatedWeights, aggregatedPrototypes,
+        globalAccuracy, globalLoss, alignmentScore, aggregationProof)
+}
+
+// commitAggregation persists an already-computed aggregate as the new global model.
+func (c *VPSAContract) commitAggregation(ctx contractapi.TransactionContextInterface,
     modelIDs []string, aggregatedWeights string, aggregatedPrototypes string,
-    globalAccuracy float64, globalLoss float64, alignmentScore float64) error {
+    globalAccuracy float64, globalLoss float64, alignmentScore float64, aggregationProof string) error {
 
     globalModel, err := c.GetGlobalModel(ctx)
     if err != nil {
@@ -406,7 +680,8 @@ func (c *VPSAContract) AggregateModels(ctx contractapi.TransactionContextInterfa
 
     sourceCount := 0
     targetCount := 0
-    
+    commitmentDigest := sha256.New()
+
     for _, modelID := range modelIDs {
         model, err := c.GetLocalModel(ctx, modelID)
         if err != nil {
@@ -419,11 +694,24 @@ func (c *VPSAContract) AggregateModels(ctx contractapi.TransactionContextInterfa
             targetCount++
         }
 
+        commitmentDigest.Write([]byte(model.PublicInputs.WeightCommitment))
+
         model.Status = "aggregated"
         modelJSON, _ := json.Marshal(model)
         ctx.GetStub().PutState(modelID, modelJSON)
     }
 
+    aggregationPublicInputs := PublicInputs{
+        DataRootHash:           fmt.Sprintf("%x", commitmentDigest.Sum(nil)),
+        PriorGlobalWeightsHash: fmt.Sprintf("%x", sha256.Sum256([]byte(globalModel.Weights))),
+        ClaimedDataSize:        len(modelIDs),
+        WeightCommitment:       fmt.Sprintf("%x", sha256.Sum256([]byte(aggregatedWeights))),
+    }
+    aggregationVerifyingKeyFingerprint, err := c.verifyProof(ctx, aggregationProof, aggregationPublicInputs)
+    if err != nil {
+        return fmt.Errorf("aggregation proof check failed: %w", err)
+    }
+
     globalModel.Version++
     globalModel.Round = config.CurrentRound
     globalModel.Weights = aggregatedWeights
@@ -446,14 +734,22 @@ func (c *VPSAContract) AggregateModels(ctx contractapi.TransactionContextInterfa
         return err
     }
 
+    epsilonSpent, err := c.getRoundEpsilon(ctx, config.CurrentRound)
+    if err != nil {
+        return err
+    }
+
     metrics := TrainingMetrics{
-        MetricID:        fmt.Sprintf("metrics-round-%d", config.CurrentRound),
-        Round:           config.CurrentRound,
-        GlobalAccuracy:  globalAccuracy,
-        GlobalLoss:      globalLoss,
-        AlignmentScore:  alignmentScore,
-        NumParticipants: len(modelIDs),
-        Timestamp:       timestamp,
+        MetricID:                           fmt.Sprintf("metrics-round-%d", config.CurrentRound),
+        Round:                              config.CurrentRound,
+        GlobalAccuracy:                     globalAccuracy,
+        GlobalLoss:                         globalLoss,
+        AlignmentScore:                     alignmentScore,
+        NumParticipants:                    len(modelIDs),
+        AggregationVerifyingKeyFingerprint: aggregationVerifyingKeyFingerprint,
+        EpsilonSpent:                       epsilonSpent,
+        Timestamp:                          timestamp,
+        DocType:                            "metrics",
     }
 
     metricsJSON, err := json.Marshal(metrics)
@@ -516,13 +812,26 @@ func (c *VPSAContract) GetAggregationConfig(ctx contractapi.TransactionContextIn
 
 // UpdateAggregationConfig updates aggregation parameters
 func (c *VPSAContract) UpdateAggregationConfig(ctx contractapi.TransactionContextInterface,
-    minClients int, sourceWeight float64, targetWeight float64, alignmentWeight float64) error {
+    minClients int, sourceWeight float64, targetWeight float64, alignmentWeight float64,
+    aggregationMode string, byzantineTolerance int, crossChannelWeight float64) error {
 
     config, err := c.GetAggregationConfig(ctx)
     if err != nil {
         return err
     }
 
+    switch aggregationMode {
+    case "fedavg", "krum", "multi-krum", "trimmed-mean", "median":
+    default:
+        return fmt.Errorf("unsupported aggregation mode: %s", aggregationMode)
+    }
+    if byzantineTolerance < 0 {
+        return fmt.Errorf("byzantineTolerance must not be negative, got %d", byzantineTolerance)
+    }
+    if crossChannelWeight < 0 || crossChannelWeight > 1 {
+        return fmt.Errorf("crossChannelWeight must be between 0 and 1, got %.4f", crossChannelWeight)
+    }
+
     timestamp, err := getTxTimestamp(ctx)
     if err != nil {
         return fmt.Errorf("failed to get transaction timestamp: %v", err)
@@ -532,6 +841,9 @@ func (c *VPSAContract) UpdateAggregationConfig(ctx contractapi.TransactionContex
     config.SourceWeight = sourceWeight
     config.TargetWeight = targetWeight
     config.AlignmentWeight = alignmentWeight
+    config.AggregationMode = aggregationMode
+    config.ByzantineTolerance = byzantineTolerance
+    config.CrossChannelWeight = crossChannelWeight
     config.LastUpdated = timestamp
 
     configJSON, err := json.Marshal(config)
@@ -0,0 +1,180 @@
+package main
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math/big"
+
+    "github.com/consensys/gnark-crypto/ecc/bn254"
+    "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// ErrProofVerificationFailed is returned when a submitted zk-SNARK proof does not verify.
+var ErrProofVerificationFailed = errors.New("zk-SNARK proof verification failed")
+
+// ErrVerifyingKeyNotSet is returned when a proof is checked before an admin has configured a verifying key.
+var ErrVerifyingKeyNotSet = errors.New("verifying key not configured for this training campaign")
+
+// PublicInputs binds a submitted local model (or aggregation) to the data and prior
+// global state it was claimed to be derived from.
+type PublicInputs struct {
+    DataRootHash           string `json:"dataRootHash"`
+    PriorGlobalWeightsHash string `json:"priorGlobalWeightsHash"`
+    ClaimedDataSize        int    `json:"claimedDataSize"`
+    WeightCommitment       string `json:"weightCommitment"`
+}
+
+// Groth16Proof is a hex-encoded (compressed, BN254) Groth16 proof.
+type Groth16Proof struct {
+    A string `json:"a"`
+    B string `json:"b"`
+    C string `json:"c"`
+}
+
+// Groth16VerifyingKey is a hex-encoded (compressed, BN254) Groth16 verifying key.
+// IC must have one entry more than the number of public inputs (the constant term).
+type Groth16VerifyingKey struct {
+    Alpha string   `json:"alpha"`
+    Beta  string   `json:"beta"`
+    Gamma string   `json:"gamma"`
+    Delta string   `json:"delta"`
+    IC    []string `json:"ic"`
+}
+
+// ProofVerifier verifies that a proof attests to the given public inputs under a
+// verifying key, so that alternate proof systems can be swapped in later.
+type ProofVerifier interface {
+    Verify(proof string, publicInputs PublicInputs, verifyingKey string) (bool, error)
+}
+
+// Groth16Verifier verifies Groth16 proofs over the BN254 curve using gnark-crypto.
+type Groth16Verifier struct{}
+
+// hashToFrElement folds an arbitrary string into a BN254 scalar field element so
+// hashes and byte strings captured in PublicInputs can be used as proof witnesses.
+func hashToFrElement(value string) fr.Element {
+    digest := sha256.Sum256([]byte(value))
+    var e fr.Element
+    e.SetBytes(digest[:])
+    return e
+}
+
+func publicWitness(pi PublicInputs) []fr.Element {
+    return []fr.Element{
+        hashToFrElement(pi.DataRootHash),
+        hashToFrElement(pi.PriorGlobalWeightsHash),
+        hashToFrElement(fmt.Sprintf("%d", pi.ClaimedDataSize)),
+        hashToFrElement(pi.WeightCommitment),
+    }
+}
+
+func decodeG1(hexStr string) (bn254.G1Affine, error) {
+    var p bn254.G1Affine
+    b, err := hex.DecodeString(hexStr)
+    if err != nil {
+        return p, err
+    }
+    if _, err := p.SetBytes(b); err != nil {
+        return p, err
+    }
+    return p, nil
+}
+
+func decodeG2(hexStr string) (bn254.G2Affine, error) {
+    var p bn254.G2Affine
+    b, err := hex.DecodeString(hexStr)
+    if err != nil {
+        return p, err
+    }
+    if _, err := p.SetBytes(b); err != nil {
+        return p, err
+    }
+    return p, nil
+}
+
+// Verify checks proof (a JSON-encoded Groth16Proof) against publicInputs using
+// verifyingKey (a JSON-encoded Groth16VerifyingKey).
+func (g *Groth16Verifier) Verify(proof string, publicInputs PublicInputs, verifyingKey string) (bool, error) {
+    var p Groth16Proof
+    if err := json.Unmarshal([]byte(proof), &p); err != nil {
+        return false, fmt.Errorf("failed to decode proof: %v", err)
+    }
+
+    var vk Groth16VerifyingKey
+    if err := json.Unmarshal([]byte(verifyingKey), &vk); err != nil {
+        return false, fmt.Errorf("failed to decode verifying key: %v", err)
+    }
+
+    witness := publicWitness(publicInputs)
+    if len(vk.IC) != len(witness)+1 {
+        return false, fmt.Errorf("verifying key has %d IC entries, expected %d", len(vk.IC), len(witness)+1)
+    }
+
+    a, err := decodeG1(p.A)
+    if err != nil {
+        return false, fmt.Errorf("invalid proof.A: %v", err)
+    }
+    b, err := decodeG2(p.B)
+    if err != nil {
+        return false, fmt.Errorf("invalid proof.B: %v", err)
+    }
+    c, err := decodeG1(p.C)
+    if err != nil {
+        return false, fmt.Errorf("invalid proof.C: %v", err)
+    }
+    alpha, err := decodeG1(vk.Alpha)
+    if err != nil {
+        return false, fmt.Errorf("invalid vk.Alpha: %v", err)
+    }
+    beta, err := decodeG2(vk.Beta)
+    if err != nil {
+        return false, fmt.Errorf("invalid vk.Beta: %v", err)
+    }
+    gamma, err := decodeG2(vk.Gamma)
+    if err != nil {
+        return false, fmt.Errorf("invalid vk.Gamma: %v", err)
+    }
+    delta, err := decodeG2(vk.Delta)
+    if err != nil {
+        return false, fmt.Errorf("invalid vk.Delta: %v", err)
+    }
+
+    ic0, err := decodeG1(vk.IC[0])
+    if err != nil {
+        return false, fmt.Errorf("invalid vk.IC[0]: %v", err)
+    }
+    var vkX bn254.G1Jac
+    vkX.FromAffine(&ic0)
+    for i, w := range witness {
+        term, err := decodeG1(vk.IC[i+1])
+        if err != nil {
+            return false, fmt.Errorf("invalid vk.IC[%d]: %v", i+1, err)
+        }
+        var termJac bn254.G1Jac
+        termJac.FromAffine(&term)
+        wBig := new(big.Int)
+        w.BigInt(wBig)
+        termJac.ScalarMultiplication(&termJac, wBig)
+        vkX.AddAssign(&termJac)
+    }
+    var vkXAffine bn254.G1Affine
+    vkXAffine.FromJacobian(&vkX)
+
+    // Groth16 verification equation: e(A,B) == e(alpha,beta) * e(vkX,gamma) * e(C,delta)
+    var negAlpha, negVkX, negC bn254.G1Affine
+    negAlpha.Neg(&alpha)
+    negVkX.Neg(&vkXAffine)
+    negC.Neg(&c)
+
+    ok, err := bn254.PairingCheck(
+        []bn254.G1Affine{a, negAlpha, negVkX, negC},
+        []bn254.G2Affine{b, beta, gamma, delta},
+    )
+    if err != nil {
+        return false, err
+    }
+    return ok, nil
+}
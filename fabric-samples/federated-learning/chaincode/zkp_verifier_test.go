@@ -0,0 +1,132 @@
+package main
+
+import (
+    "encoding/hex"
+    "encoding/json"
+    "math/big"
+    "testing"
+
+    "github.com/consensys/gnark-crypto/ecc/bn254"
+)
+
+// buildSatisfyingProof hand-constructs a (proof, verifying key) pair satisfying the
+// Groth16 pairing equation e(A,B) == e(alpha,beta)*e(vkX,gamma)*e(C,delta) for
+// publicInputs, without a real circuit: beta, gamma and delta are all set to the G2
+// generator, so the check degenerates to A == alpha + vkX + C as G1 points, which
+// lets the test pick alpha, the IC basis and C freely and solve for A.
+func buildSatisfyingProof(t *testing.T, publicInputs PublicInputs) (Groth16Proof, Groth16VerifyingKey) {
+    t.Helper()
+
+    _, _, g1Gen, g2Gen := bn254.Generators()
+
+    alphaScalar := big.NewInt(7)
+    cScalar := big.NewInt(11)
+    icScalars := []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(13), big.NewInt(17), big.NewInt(19)}
+
+    witness := publicWitness(publicInputs)
+    if len(icScalars) != len(witness)+1 {
+        t.Fatalf("icScalars has %d entries, expected %d", len(icScalars), len(witness)+1)
+    }
+
+    vkXScalar := new(big.Int).Set(icScalars[0])
+    for i, w := range witness {
+        wBig := new(big.Int)
+        w.BigInt(wBig)
+        term := new(big.Int).Mul(icScalars[i+1], wBig)
+        vkXScalar.Add(vkXScalar, term)
+    }
+
+    aScalar := new(big.Int).Add(alphaScalar, vkXScalar)
+    aScalar.Add(aScalar, cScalar)
+
+    var a, alpha, c bn254.G1Affine
+    a.ScalarMultiplication(&g1Gen, aScalar)
+    alpha.ScalarMultiplication(&g1Gen, alphaScalar)
+    c.ScalarMultiplication(&g1Gen, cScalar)
+
+    ic := make([]string, len(icScalars))
+    for i, s := range icScalars {
+        var p bn254.G1Affine
+        p.ScalarMultiplication(&g1Gen, s)
+        b := p.Bytes()
+        ic[i] = hex.EncodeToString(b[:])
+    }
+
+    bBytes := g2Gen.Bytes()
+    aBytes := a.Bytes()
+    alphaBytes := alpha.Bytes()
+    cBytes := c.Bytes()
+
+    proof := Groth16Proof{
+        A: hex.EncodeToString(aBytes[:]),
+        B: hex.EncodeToString(bBytes[:]),
+        C: hex.EncodeToString(cBytes[:]),
+    }
+    vk := Groth16VerifyingKey{
+        Alpha: hex.EncodeToString(alphaBytes[:]),
+        Beta:  hex.EncodeToString(bBytes[:]),
+        Gamma: hex.EncodeToString(bBytes[:]),
+        Delta: hex.EncodeToString(bBytes[:]),
+        IC:    ic,
+    }
+    return proof, vk
+}
+
+func TestGroth16VerifierAcceptsSatisfyingProof(t *testing.T) {
+    publicInputs := PublicInputs{
+        DataRootHash:           "root-hash",
+        PriorGlobalWeightsHash: "prior-weights-hash",
+        ClaimedDataSize:        42,
+        WeightCommitment:       "commitment",
+    }
+    proof, vk := buildSatisfyingProof(t, publicInputs)
+
+    proofJSON, err := json.Marshal(proof)
+    if err != nil {
+        t.Fatalf("failed to marshal proof: %v", err)
+    }
+    vkJSON, err := json.Marshal(vk)
+    if err != nil {
+        t.Fatalf("failed to marshal verifying key: %v", err)
+    }
+
+    verifier := &Groth16Verifier{}
+    ok, err := verifier.Verify(string(proofJSON), publicInputs, string(vkJSON))
+    if err != nil {
+        t.Fatalf("Verify returned error: %v", err)
+    }
+    if !ok {
+        t.Fatal("Verify rejected an algebraically satisfying proof")
+    }
+}
+
+func TestGroth16VerifierRejectsMismatchedPublicInputs(t *testing.T) {
+    publicInputs := PublicInputs{
+        DataRootHash:           "root-hash",
+        PriorGlobalWeightsHash: "prior-weights-hash",
+        ClaimedDataSize:        42,
+        WeightCommitment:       "commitment",
+    }
+    proof, vk := buildSatisfyingProof(t, publicInputs)
+
+    proofJSON, err := json.Marshal(proof)
+    if err != nil {
+        t.Fatalf("failed to marshal proof: %v", err)
+    }
+    vkJSON, err := json.Marshal(vk)
+    if err != nil {
+        t.Fatalf("failed to marshal verifying key: %v", err)
+    }
+
+    tamperedInputs := publicInputs
+    tamperedInputs.ClaimedDataSize = 43
+
+    verifier := &Groth16Verifier{}
+    ok, err := verifier.Verify(string(proofJSON), tamperedInputs, string(vkJSON))
+    if err != nil {
+        t.Fatalf("Verify returned error: %v", err)
+    }
+    if ok {
+        t.Fatal("Verify accepted a proof against tampered public inputs")
+    }
+}